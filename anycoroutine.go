@@ -0,0 +1,205 @@
+package coroutine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudlibraries/cast"
+)
+
+// AnyCoroutine is the interface{}-based Coroutine kept for backward
+// compatibility with code predating the generic API; prefer
+// Coroutine[In, Out] in new code.
+type AnyCoroutine = Coroutine[[]interface{}, []interface{}]
+
+// Start wraps and starts a ID up.
+// It is thread-safe, and it should be called before other funcs.
+func Start(fn func(id ID) error) error {
+	co := Wrap[[]interface{}, []interface{}](func(co *AnyCoroutine, args []interface{}) ([]interface{}, error) {
+		return nil, fn(co.id)
+	})
+
+	return co.Call(nil)
+}
+
+// Create wraps and yields a ID with no args, waits for a resume.
+// It is not thread-safe, and it should be called before other funcs.
+// Call `Resume` after `Create` to start up a ID.
+// Pass WithContext to scope the coroutine's entire lifetime to a
+// parent context.
+func Create(fn func(id ID, inData ...interface{}) error, opts ...Option) ID {
+	co := Wrap[[]interface{}, []interface{}](func(co *AnyCoroutine, args []interface{}) ([]interface{}, error) {
+		inData, err := co.Yield([]interface{}{})
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, fn(co.id, inData...)
+	}, opts...)
+
+	// No error would be caused here.
+	go co.Call(nil)
+
+	return co.id
+}
+
+// Call launch a ID that is already wrapped.
+// It is not thread-safe, and it can only be called beside after Wrap.
+// Call `Call` After `Wrap` to start up a ID.
+// It returns ErrCoroutineMissing if id is unknown and ErrAlreadyStarted
+// if the coroutine was already launched, rather than panicking.
+func Call(id ID, args ...interface{}) error {
+	co, err := findAnyCoroutine(id)
+	if err != nil {
+		return err
+	}
+
+	return co.Call(args)
+}
+
+// CallContext is like Call, but ctx scopes the coroutine's lifetime for
+// this run: once ctx is done, the coroutine transitions to Dead and
+// any pending Resume/Yield is released with ErrCanceled.
+func CallContext(ctx context.Context, id ID, args ...interface{}) error {
+	co, err := findAnyCoroutine(id)
+	if err != nil {
+		return err
+	}
+
+	co.writeCtx(ctx)
+
+	return co.Call(args)
+}
+
+// Resume continues a suspend ID, passing data in and out.
+// It is thread-safe, and it can only be called in other Goroutine.
+// Call `Resume` after `Create` to start up a ID.
+// Call `Resume` after `Yield` to continue a ID.
+// It returns ErrCoroutineMissing, ErrCoroutineDead or ErrSuspendTimeout
+// instead of panicking; use errors.Is to distinguish a legitimate
+// ErrCoroutineDead from the others.
+func Resume(id ID, inData ...interface{}) ([]interface{}, error) {
+	co, err := findAnyCoroutine(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return co.Resume(inData)
+}
+
+// ResumeContext is like Resume, but ctx additionally bounds this single
+// resume/yield handshake: if ctx is done before the peer yields, the
+// coroutine transitions to Dead and ErrCanceled is returned.
+func ResumeContext(ctx context.Context, id ID, inData ...interface{}) ([]interface{}, error) {
+	co, err := findAnyCoroutine(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return co.ResumeContext(ctx, inData)
+}
+
+// TryResume likes Resume, but checks status instead of waiting for status.
+// It is thread-safe, and it can only be called in other Goroutine.
+// Call `TryResume` after `Create` to start up a ID.
+// Call `TryResume` after `Yield` to continue a ID.
+// It returns ErrCoroutineDead if the ID is not currently Suspended.
+func TryResume(id ID, inData ...interface{}) ([]interface{}, error) {
+	co, err := findAnyCoroutine(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return co.TryResume(inData)
+}
+
+// AsyncResume likes Resume, but works async.
+// It is thread-safe, and it can only be called in other Goroutine.
+// Call `AsyncResume` after `Create` to start up a ID.
+// Call `AsyncResume` after `Yield` to continue a ID.
+// The returned channel carries ErrCoroutineMissing, ErrCoroutineDead or
+// ErrSuspendTimeout instead of a panic.
+func AsyncResume(id ID, fn func(outData ...interface{}), inData ...interface{}) chan error {
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				errCh <- cast.ToError(v)
+			}
+		}()
+
+		co, err := findAnyCoroutine(id)
+		if err != nil {
+			errCh <- err
+
+			return
+		}
+
+		outData, err := co.Resume(inData)
+		if err != nil {
+			errCh <- err
+
+			return
+		}
+
+		fn(outData...)
+	}()
+
+	return errCh
+}
+
+// Yield suspends a running coroutine, passing data in and out.
+// It is not thread-safe, and it can only be called in coroutine.fn.
+// Call `Resume`, `TryResume` or `AsyncResume`
+// after `Yield` to continue a ID.
+// It returns ErrCoroutineMissing or ErrSuspendTimeout instead of panicking.
+func Yield(id ID, outData ...interface{}) ([]interface{}, error) {
+	co, err := findAnyCoroutine(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return co.Yield(outData)
+}
+
+// YieldContext is like Yield, but ctx additionally bounds this single
+// resume/yield handshake: if ctx is done before a peer resumes, the
+// coroutine transitions to Dead and ErrCanceled is returned.
+func YieldContext(ctx context.Context, id ID, outData ...interface{}) ([]interface{}, error) {
+	co, err := findAnyCoroutine(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return co.YieldContext(ctx, outData)
+}
+
+// Terminate hands a ID's final value out without waiting for a
+// response, unlike Yield. See Coroutine.Terminate; call it from a
+// defer to report a value on the way out without risking the deadlock
+// a plain Yield would invite there.
+func Terminate(id ID, outData ...interface{}) error {
+	co, err := findAnyCoroutine(id)
+	if err != nil {
+		return err
+	}
+
+	co.Terminate(outData)
+
+	return nil
+}
+
+func findAnyCoroutine(id ID) (*AnyCoroutine, error) {
+	v, ok := coroutines.Load(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: [%s]", ErrCoroutineMissing, id)
+	}
+
+	co, ok := v.(*AnyCoroutine)
+	if !ok {
+		return nil, fmt.Errorf("%w: [%s]", ErrCoroutineMissing, id)
+	}
+
+	return co, nil
+}
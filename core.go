@@ -0,0 +1,231 @@
+package coroutine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+var _ killer = (*core)(nil)
+
+type (
+	// StatusType is an alias for string.
+	StatusType = string
+
+	// ID is the unique identifier for coroutine.
+	ID = string
+)
+
+const (
+	// Created means ID is created and not started.
+	Created = "Created"
+
+	// Suspended means ID is started and yielded.
+	Suspended = "Suspended"
+
+	// Running means ID is started and running.
+	Running = "Running"
+
+	// Terminating means fn is winding down: it has committed to
+	// returning (often from a defer) and is delivering one last value
+	// via Terminate rather than a full Yield handshake. Resume drains
+	// that value if it is already waiting on it, but any Resume issued
+	// from this point on fails fast with ErrCoroutineDead instead of
+	// racing into a handshake nothing will complete. A deferred Yield,
+	// unlike a deferred Terminate, does not reach this status: it still
+	// runs the full handshake, so it blocks until expire if no Resume is
+	// pending or ever comes.
+	Terminating = "Terminating"
+
+	// Dead means ID not created or ended.
+	Dead = "Dead"
+)
+
+// coroutines indexes every live Coroutine[In, Out], of whatever
+// instantiation, by ID so ID-based introspection (Status, and the
+// legacy AnyCoroutine shim) does not need to know In/Out.
+var coroutines sync.Map
+
+// core holds the bookkeeping shared by every Coroutine[In, Out]
+// instantiation: identity, status and the context that scopes its
+// lifetime. It is embedded in Coroutine so Option can configure any
+// instantiation uniformly.
+type core struct {
+	id          ID
+	status      StatusType
+	ctx         context.Context
+	cancel      context.CancelFunc
+	killCh      chan struct{}
+	killOnce    sync.Once
+	deadCh      chan struct{}
+	deadOnce    sync.Once
+	mutexStatus *sync.Mutex
+	mutexResume *sync.Mutex
+}
+
+func newCore(opts ...Option) *core {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := &core{
+		id:          uuid.NewV4().String(),
+		status:      Created,
+		ctx:         ctx,
+		cancel:      cancel,
+		killCh:      make(chan struct{}),
+		deadCh:      make(chan struct{}),
+		mutexStatus: &sync.Mutex{},
+		mutexResume: &sync.Mutex{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// ID returns the coroutine's unique identifier.
+func (c *core) ID() ID {
+	return c.id
+}
+
+// Status reports the coroutine's current status.
+func (c *core) Status() StatusType {
+	return c.readSyncStatus()
+}
+
+func (c *core) String() string {
+	return fmt.Sprintf("[%s]", c.id)
+}
+
+func (c *core) writeSyncStatus(status StatusType) {
+	c.mutexStatus.Lock()
+	c.status = status
+	c.mutexStatus.Unlock()
+
+	if status == Dead {
+		c.deadOnce.Do(func() { close(c.deadCh) })
+	}
+}
+
+// kill forcibly transitions the coroutine to Dead, waking any
+// Resume/Yield currently blocked on it with ErrCoroutineDead and
+// canceling its context. It is idempotent.
+func (c *core) kill() {
+	c.killOnce.Do(func() {
+		c.writeSyncStatus(Dead)
+		close(c.killCh)
+		coroutines.Delete(c.id)
+		c.readCancel()()
+	})
+}
+
+// readCtx returns the coroutine's current lifetime context. Reading it
+// under mutexStatus, the same lock writeCtx takes, keeps a concurrent
+// CallContext-style rebind from racing with resume/yield's reads of it.
+func (c *core) readCtx() context.Context {
+	c.mutexStatus.Lock()
+	defer c.mutexStatus.Unlock()
+
+	return c.ctx
+}
+
+// readCancel returns the cancel func paired with the context readCtx
+// would currently return.
+func (c *core) readCancel() context.CancelFunc {
+	c.mutexStatus.Lock()
+	defer c.mutexStatus.Unlock()
+
+	return c.cancel
+}
+
+// writeCtx rebinds the coroutine's lifetime context to a cancelable
+// child of ctx, replacing both ctx and cancel together under
+// mutexStatus so kill and resume/yield never observe one updated
+// without the other.
+func (c *core) writeCtx(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	c.mutexStatus.Lock()
+	c.ctx = ctx
+	c.cancel = cancel
+	c.mutexStatus.Unlock()
+}
+
+// dead returns a channel that is closed once the coroutine transitions
+// to Dead, however that happens: normal completion, timeout,
+// cancellation or kill.
+func (c *core) dead() <-chan struct{} {
+	return c.deadCh
+}
+
+func (c *core) readSyncStatus() StatusType {
+	c.mutexStatus.Lock()
+	defer c.mutexStatus.Unlock()
+
+	return c.status
+}
+
+// statusser is satisfied by every Coroutine[In, Out] instantiation and
+// lets Status(id) look a coroutine up without knowing its type
+// parameters.
+type statusser interface {
+	Status() StatusType
+}
+
+// Status shows the status of a ID.
+// It is thread-safe, and it can be called in any Goroutine.
+// Call `Status` anywhere you need.
+func Status(id ID) StatusType {
+	v, ok := coroutines.Load(id)
+	if !ok {
+		return Dead
+	}
+
+	return v.(statusser).Status()
+}
+
+// killer is satisfied by every Coroutine[In, Out] instantiation and
+// lets Kill/Wait act on a coroutine without knowing its type
+// parameters.
+type killer interface {
+	kill()
+	dead() <-chan struct{}
+}
+
+// Kill forcibly terminates the coroutine identified by id: any
+// Resume/Yield currently blocked on it returns ErrCoroutineDead. Kill
+// also removes id, so any later Resume/Yield by id returns
+// ErrCoroutineMissing, as does a second Kill. It is thread-safe.
+func Kill(id ID) error {
+	v, ok := coroutines.Load(id)
+	if !ok {
+		return fmt.Errorf("%w: [%s]", ErrCoroutineMissing, id)
+	}
+
+	v.(killer).kill()
+
+	return nil
+}
+
+// Stop is an alias for Kill.
+func Stop(id ID) error {
+	return Kill(id)
+}
+
+// Wait blocks until the coroutine identified by id terminates, however
+// that happens: normal completion, timeout, cancellation or Kill. Like
+// Status, a missing id is treated as already terminated rather than an
+// error.
+func Wait(id ID) error {
+	v, ok := coroutines.Load(id)
+	if !ok {
+		return nil
+	}
+
+	<-v.(killer).dead()
+
+	return nil
+}
@@ -1,268 +1,288 @@
 package coroutine
 
 import (
+	"context"
 	"fmt"
-	"sync"
 	"time"
 
 	"github.com/cloudlibraries/cast"
-	uuid "github.com/satori/go.uuid"
 )
 
-type (
-	// StatusType is an alias for string.
-	StatusType = string
-
-	// ID is the unique identifier for coroutine.
-	ID = string
-
-	// Coroutine is a simulator struct for coroutine.
-	Coroutine struct {
-		id          ID
-		status      StatusType
-		inCh        chan []interface{}
-		outCh       chan []interface{}
-		fn          func(id ID, args ...interface{}) error
-		mutexStatus *sync.Mutex
-		mutexResume *sync.Mutex
-	}
-)
-
-const (
-	// Created means ID is created and not started.
-	Created = "Created"
-
-	// Suspended means ID is started and yielded.
-	Suspended = "Suspended"
-
-	// Running means ID is started and running.
-	Running = "Running"
-
-	// Dead means ID not created or ended.
-	Dead = "Dead"
-)
+// Coroutine is a type-safe simulator for a coroutine that accepts In
+// and produces Out on each suspend point, replacing the pervasive
+// cast/type-assertion pattern of the []interface{} API. For
+// interface{}-based code, see AnyCoroutine.
+type Coroutine[In, Out any] struct {
+	*core
+	inCh  chan In
+	outCh chan Out
+	fn    func(co *Coroutine[In, Out], in In) (Out, error)
+}
 
-var coroutines sync.Map
+// Wrap creates a type-safe coroutine from fn. The coroutine is
+// Created but not started; call Call to launch it. Pass WithContext
+// to scope its entire lifetime to a parent context.
+func Wrap[In, Out any](fn func(co *Coroutine[In, Out], in In) (Out, error), opts ...Option) *Coroutine[In, Out] {
+	co := &Coroutine[In, Out]{
+		core:  newCore(opts...),
+		inCh:  make(chan In, 1),
+		outCh: make(chan Out, 1),
+		fn:    fn,
+	}
+	coroutines.Store(co.id, co)
 
-// Start wraps and starts a ID up.
-// It is thread-safe, and it should be called before other funcs.
-func Start(fn func(id ID) error) error {
-	return Call(Wrap(func(id ID, args ...interface{}) error {
-		return fn(id)
-	}))
+	return co
 }
 
-// Wrap wraps a ID and waits for a startup.
-// It is thread-safe, and it should be called before other funcs.
-// Call `Call` after `Wrap` to start up a ID.
-func Wrap(fn func(id ID, args ...interface{}) error) ID {
-	id := uuid.NewV4().String()
-
-	c := &Coroutine{
-		id:          id,
-		status:      Created,
-		inCh:        make(chan []interface{}, 1),
-		outCh:       make(chan []interface{}, 1),
-		fn:          fn,
-		mutexStatus: &sync.Mutex{},
-		mutexResume: &sync.Mutex{},
+// Call launches a wrapped coroutine, running fn to completion (which
+// may involve any number of Yield/Resume handshakes along the way).
+// It returns ErrAlreadyStarted if the coroutine was already launched.
+func (co *Coroutine[In, Out]) Call(in In) error {
+	panicVal, err := co.callCatchingPanic(in)
+	if panicVal != nil {
+		return cast.ToError(panicVal)
 	}
-	coroutines.Store(id, c)
 
-	return id
+	return err
 }
 
-// Call launch a ID that is already wrapped.
-// It is not thread-safe, and it can only be called beside after Wrap.
-// Call `Call` After `Wrap` to start up a ID.
-func Call(id ID, args ...interface{}) error {
-	c := findCoroutine(id)
-	c.writeSyncStatus(Running)
+// callCatchingPanic runs fn like Call, but returns a recovered panic
+// value verbatim instead of folding it into err via cast.ToError, so a
+// supervisor like Pool can tell a panic apart from an ordinary error.
+func (co *Coroutine[In, Out]) callCatchingPanic(in In) (panicVal interface{}, err error) {
+	if co.readSyncStatus() != Created {
+		return nil, ErrAlreadyStarted
+	}
+
+	co.writeSyncStatus(Running)
 
-	return func() (err error) {
+	func() {
 		defer func() {
 			if v := recover(); v != nil {
-				err = cast.ToError(v)
+				panicVal = v
 			}
 		}()
 		defer func() {
-			coroutines.Delete(id)
+			coroutines.Delete(co.id)
+			co.writeSyncStatus(Dead)
 		}()
 
-		return c.fn(id, args...)
+		_, err = co.fn(co, in)
 	}()
-}
-
-// Create wraps and yields a ID with no args, waits for a resume.
-// It is not thread-safe, and it should be called before other funcs.
-// Call `Resume` after `Create` to start up a ID.
-func Create(fn func(id ID, inData ...interface{}) error) ID {
-	id := Wrap(func(id ID, args ...interface{}) error {
-		inData := Yield(id)
-		return fn(id, inData...)
-	})
 
-	// No error would be caused here.
-	go Call(id)
-
-	return id
+	return panicVal, err
 }
 
-// Resume continues a suspend ID, passing data in and out.
-// It is thread-safe, and it can only be called in other Goroutine.
-// Call `Resume` after `Create` to start up a ID.
-// Call `Resume` after `Yield` to continue a ID.
-func Resume(id ID, inData ...interface{}) ([]interface{}, bool) {
-	c := findCoroutine(id)
-
-	c.mutexResume.Lock()
-	defer c.mutexResume.Unlock()
+// Kill forcibly terminates the coroutine: any Resume/Yield currently
+// blocked on it returns ErrCoroutineDead, and any later Resume/Yield
+// does too. It is idempotent and always returns nil.
+func (co *Coroutine[In, Out]) Kill() error {
+	co.kill()
 
-	if c.readSyncStatus() == Dead {
-		return nil, false
-	}
-	outData := c.resume(inData)
+	return nil
+}
 
-	return outData, true
+// Stop is an alias for Kill.
+func (co *Coroutine[In, Out]) Stop() error {
+	return co.Kill()
 }
 
-// TryResume likes Resume, but checks status instead of waiting for status.
-// It is thread-safe, and it can only be called in other Goroutine.
-// Call `TryResume` after `Create` to start up a ID.
-// Call `TryResume` after `Yield` to continue a ID.
-func TryResume(id ID, inData ...interface{}) ([]interface{}, bool) {
-	c := findCoroutine(id)
+// Wait blocks until the coroutine terminates, however that happens:
+// normal completion, timeout, cancellation or Kill.
+func (co *Coroutine[In, Out]) Wait() error {
+	<-co.dead()
 
-	c.mutexResume.Lock()
-	defer c.mutexResume.Unlock()
+	return nil
+}
 
-	if c.readSyncStatus() != Suspended {
-		return nil, false
-	}
-	outData := c.resume(inData)
+// Resume continues a suspended coroutine, passing in in and returning
+// the value it yielded out. It returns ErrCoroutineDead,
+// ErrSuspendTimeout or ErrCanceled instead of panicking.
+func (co *Coroutine[In, Out]) Resume(in In) (Out, error) {
+	return co.resume(context.Background(), in, false)
+}
 
-	return outData, true
+// ResumeContext is like Resume, but ctx additionally bounds this single
+// handshake: if ctx is done before the peer yields, the coroutine
+// transitions to Dead and ErrCanceled is returned.
+func (co *Coroutine[In, Out]) ResumeContext(ctx context.Context, in In) (Out, error) {
+	return co.resume(ctx, in, false)
 }
 
-// AsyncResume likes Resume, but works async.
-// It is thread-safe, and it can only be called in other Goroutine.
-// Call `AsyncResume` after `Create` to start up a ID.
-// Call `AsyncResume` after `Yield` to continue a ID.
-func AsyncResume(id ID, fn func(outData ...interface{}), inData ...interface{}) chan error {
-	errCh := make(chan error, 1)
+// TryResume is like Resume, but checks status instead of waiting for
+// it: it returns ErrCoroutineDead immediately unless the coroutine is
+// currently Suspended.
+func (co *Coroutine[In, Out]) TryResume(in In) (Out, error) {
+	return co.resume(context.Background(), in, true)
+}
 
-	go func() {
-		defer func() {
-			if v := recover(); v != nil {
-				errCh <- cast.ToError(v)
-			}
-		}()
+func (co *Coroutine[In, Out]) resume(ctx context.Context, in In, strict bool) (Out, error) {
+	var zero Out
 
-		co := findCoroutine(id)
-		co.mutexResume.Lock()
-		defer co.mutexResume.Unlock()
+	co.mutexResume.Lock()
+	defer co.mutexResume.Unlock()
 
-		if co.readSyncStatus() == Dead {
-			panic(fmt.Errorf("coroutine is dead: %s", co))
-		}
-		outData := co.resume(inData)
+	status := co.readSyncStatus()
+	if strict && status != Suspended {
+		return zero, ErrCoroutineDead
+	}
 
-		fn(outData...)
-	}()
+	if !strict && (status == Dead || status == Terminating) {
+		return zero, ErrCoroutineDead
+	}
 
-	return errCh
-}
+	// Read once: co.ctx can be rebound concurrently (CallContext-style),
+	// and re-reading it directly inside each select below would race
+	// with that write.
+	coCtx := co.readCtx()
 
-// Yield suspends a running coroutine, passing data in and out.
-// It is not thread-safe, and it can only be called in coroutine.fn.
-// Call `Resume`, `TryResume` or `AsyncResume`
-// after `Yield` to continue a ID.
-func Yield(id ID, outData ...interface{}) []interface{} {
-	c := findCoroutine(id)
-	c.writeSyncStatus(Suspended)
-	inData := c.yield(outData)
-	c.writeSyncStatus(Running)
-
-	return inData
-}
+	var out Out
 
-// Status shows the status of a ID.
-// It is thread-safe, and it can be called in any Goroutine.
-// Call `Status` anywhere you need.
-func Status(id ID) StatusType {
-	v, ok := coroutines.Load(id)
-	if !ok {
-		return Dead
+	select {
+	case out = <-co.outCh:
+		break
+	case <-co.killCh:
+		return zero, ErrCoroutineDead
+	case <-co.dead():
+		return zero, ErrCoroutineDead
+	case <-ctx.Done():
+		co.writeSyncStatus(Dead)
+
+		return zero, fmt.Errorf("%w: %v", ErrCanceled, co)
+	case <-coCtx.Done():
+		co.writeSyncStatus(Dead)
+
+		return zero, fmt.Errorf("%w: %v", ErrCanceled, co)
+	case <-time.After(time.Duration(expire) * time.Second):
+		return zero, fmt.Errorf("%w: %v", ErrSuspendTimeout, co)
 	}
-	c := v.(*Coroutine)
 
-	return c.readSyncStatus()
-}
+	// fn handed out this value from a defer via Terminate rather than a
+	// Yield it expects a response to: deliver it without attempting the
+	// second leg of the handshake, which nothing will ever complete. By
+	// now Call may already have moved it on from Terminating to Dead.
+	if s := co.readSyncStatus(); s == Terminating || s == Dead {
+		co.writeSyncStatus(Dead)
 
-func findCoroutine(id ID) *Coroutine {
-	v, ok := coroutines.Load(id)
-	if !ok {
-		panic(fmt.Errorf("coroutine missing: [%s]", id))
+		return out, nil
 	}
 
-	return v.(*Coroutine)
-}
+	select {
+	case co.inCh <- in:
+		break
+	case <-co.killCh:
+		return zero, ErrCoroutineDead
+	case <-co.dead():
+		return zero, ErrCoroutineDead
+	case <-ctx.Done():
+		co.writeSyncStatus(Dead)
+
+		return zero, fmt.Errorf("%w: %v", ErrCanceled, co)
+	case <-coCtx.Done():
+		co.writeSyncStatus(Dead)
+
+		return zero, fmt.Errorf("%w: %v", ErrCanceled, co)
+	case <-time.After(time.Duration(expire) * time.Second):
+		return zero, fmt.Errorf("%w: %v", ErrSuspendTimeout, co)
+	}
 
-func (c *Coroutine) String() string {
-	return fmt.Sprintf("[%s]", c.id)
+	return out, nil
 }
 
-func (c *Coroutine) writeSyncStatus(status StatusType) {
-	c.mutexStatus.Lock()
-	defer c.mutexStatus.Unlock()
-	c.status = status
+// Yield suspends the running coroutine, passing out out and blocking
+// until a peer resumes it with the next In. It is not thread-safe, and
+// it can only be called from fn (or something fn calls synchronously).
+//
+// Yield always waits for that next Resume, including when called from a
+// defer: if one happens to already be pending it completes like any
+// other Yield, but if fn is winding down with no Resume outstanding,
+// nothing will ever send one, and the wait blocks until expire elapses.
+// That bound can't be tightened from inside Yield itself — it is the
+// goroutine that would need to give up waiting. Use Terminate instead
+// for a final value a defer must always report immediately, pending
+// Resume or not.
+func (co *Coroutine[In, Out]) Yield(out Out) (In, error) {
+	return co.yield(context.Background(), out)
 }
 
-func (c *Coroutine) readSyncStatus() StatusType {
-	c.mutexStatus.Lock()
-	defer c.mutexStatus.Unlock()
-
-	return c.status
+// YieldContext is like Yield, but ctx additionally bounds this single
+// handshake: if ctx is done before a peer resumes, the coroutine
+// transitions to Dead and ErrCanceled is returned.
+func (co *Coroutine[In, Out]) YieldContext(ctx context.Context, out Out) (In, error) {
+	return co.yield(ctx, out)
 }
 
-func (c *Coroutine) resume(inData []interface{}) []interface{} {
-	var outData []interface{}
+// Terminate hands out out as fn's final value without waiting for a
+// response, unlike Yield. Call it from a defer so a value is always
+// reported on the way out, including on an early return or a
+// panic's unwind, without the deadlock a plain Yield would risk there:
+// nothing is ever going to Resume a handshake fn has already decided
+// to end. A Resume already waiting receives out; any Resume from this
+// point on returns ErrCoroutineDead immediately.
+func (co *Coroutine[In, Out]) Terminate(out Out) {
+	co.writeSyncStatus(Terminating)
 
 	select {
-	case outData = <-c.outCh:
-		break
-	case <-time.After(time.Duration(expire) * time.Second):
-		panic(fmt.Errorf("coroutine suspended timeout: %v", c))
+	case co.outCh <- out:
+	default:
 	}
+}
 
-	select {
-	case c.inCh <- inData:
-		break
-	case <-time.After(time.Duration(expire) * time.Second):
-		panic(fmt.Errorf("coroutine suspended timeout: %v", c))
-	}
+func (co *Coroutine[In, Out]) yield(ctx context.Context, out Out) (In, error) {
+	var zero In
 
-	return outData
-}
+	co.writeSyncStatus(Suspended)
 
-func (c *Coroutine) yield(outData []interface{}) []interface{} {
-	var inData []interface{}
+	// Read once: co.ctx can be rebound concurrently (CallContext-style),
+	// and re-reading it directly inside each select below would race
+	// with that write.
+	coCtx := co.readCtx()
 
 	select {
-	case c.outCh <- outData:
+	case co.outCh <- out:
 		break
+	case <-co.killCh:
+		return zero, ErrCoroutineDead
+	case <-co.dead():
+		return zero, ErrCoroutineDead
+	case <-ctx.Done():
+		co.writeSyncStatus(Dead)
+
+		return zero, fmt.Errorf("%w: %v", ErrCanceled, co)
+	case <-coCtx.Done():
+		co.writeSyncStatus(Dead)
+
+		return zero, fmt.Errorf("%w: %v", ErrCanceled, co)
 	case <-time.After(time.Duration(expire) * time.Second):
-		c.writeSyncStatus(Dead)
-		panic(fmt.Errorf("coroutine suspended timeout: %v", c))
+		co.writeSyncStatus(Dead)
+
+		return zero, fmt.Errorf("%w: %v", ErrSuspendTimeout, co)
 	}
 
+	var in In
+
 	select {
-	case inData = <-c.inCh:
+	case in = <-co.inCh:
 		break
+	case <-co.killCh:
+		return zero, ErrCoroutineDead
+	case <-co.dead():
+		return zero, ErrCoroutineDead
+	case <-ctx.Done():
+		co.writeSyncStatus(Dead)
+
+		return zero, fmt.Errorf("%w: %v", ErrCanceled, co)
+	case <-coCtx.Done():
+		co.writeSyncStatus(Dead)
+
+		return zero, fmt.Errorf("%w: %v", ErrCanceled, co)
 	case <-time.After(time.Duration(expire) * time.Second):
-		c.writeSyncStatus(Dead)
-		panic(fmt.Errorf("coroutine suspended timeout: %v", c))
+		co.writeSyncStatus(Dead)
+
+		return zero, fmt.Errorf("%w: %v", ErrSuspendTimeout, co)
 	}
 
-	return inData
+	co.writeSyncStatus(Running)
+
+	return in, nil
 }
@@ -1,61 +1,172 @@
 package coroutine_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/frankban/quicktest"
-	"github.com/golibraries/coroutine"
-	"github.com/golibraries/safe"
+
+	"github.com/cloudlibraries/coroutine"
 )
 
 func TestCreate(t *testing.T) {
 	c := New(t)
 
 	c.Run("Create", func(c *C) {
-		co, err := coroutine.Create(func(co *coroutine.Coroutine, args ...any) error {
-			output, err := co.Yield("Hello")
-			if err != nil {
-				return err
-			}
-			c.Assert(output, DeepEquals, []any{"World"})
+		id := coroutine.Create(func(id coroutine.ID, inData ...interface{}) error {
+			_, err := coroutine.Yield(id, "Hello")
+
+			return err
+		})
+
+		outData, err := coroutine.Resume(id)
+		c.Assert(err, IsNil)
+		c.Assert(outData, DeepEquals, []interface{}{})
 
+		outData, err = coroutine.Resume(id, "World")
+		c.Assert(err, IsNil)
+		c.Assert(outData, DeepEquals, []interface{}{"Hello"})
+	})
+}
+
+func TestResumeMissing(t *testing.T) {
+	c := New(t)
+
+	c.Run("ResumeMissing", func(c *C) {
+		_, err := coroutine.Resume("does-not-exist")
+		c.Assert(errors.Is(err, coroutine.ErrCoroutineMissing), Equals, true)
+	})
+}
+
+func TestResumeDead(t *testing.T) {
+	c := New(t)
+
+	c.Run("ResumeDead", func(c *C) {
+		id := coroutine.Create(func(id coroutine.ID, inData ...interface{}) error {
 			return nil
 		})
+
+		_, err := coroutine.Resume(id)
 		c.Assert(err, IsNil)
+		c.Assert(coroutine.Wait(id), IsNil)
+
+		_, err = coroutine.Resume(id)
+		c.Assert(errors.Is(err, coroutine.ErrCoroutineMissing), Equals, true)
+	})
+}
 
-		output, err := co.Resume()
+func TestWithContextCancel(t *testing.T) {
+	c := New(t)
+
+	c.Run("WithContextCancel", func(c *C) {
+		ctx, cancel := context.WithCancel(context.Background())
+		errCh := make(chan error, 1)
+
+		id := coroutine.Create(func(id coroutine.ID, inData ...interface{}) error {
+			_, err := coroutine.Yield(id, "Hello")
+			errCh <- err
+
+			return err
+		}, coroutine.WithContext(ctx))
+
+		_, err := coroutine.Resume(id)
 		c.Assert(err, IsNil)
-		c.Assert(output, DeepEquals, []any{})
 
-		output, err = co.Resume("World")
+		cancel()
 
+		err = <-errCh
+		c.Assert(errors.Is(err, coroutine.ErrCanceled), Equals, true)
+	})
+}
+
+func TestGenericCoroutine(t *testing.T) {
+	c := New(t)
+
+	c.Run("GenericCoroutine", func(c *C) {
+		co := coroutine.Wrap(func(co *coroutine.Coroutine[int, string], in int) (string, error) {
+			n1, err := co.Yield(fmt.Sprintf("got %d", in))
+			if err != nil {
+				return "", err
+			}
+
+			n2, err := co.Yield(fmt.Sprintf("got %d", n1))
+			if err != nil {
+				return "", err
+			}
+
+			return fmt.Sprintf("got %d", n2), nil
+		})
+
+		go func() {
+			_ = co.Call(1)
+		}()
+
+		out, err := co.Resume(0)
+		c.Assert(err, IsNil)
+		c.Assert(out, Equals, "got 1")
+
+		out, err = co.Resume(2)
 		c.Assert(err, IsNil)
-		c.Assert(output, DeepEquals, "Hello")
+		c.Assert(out, Equals, "got 0")
 	})
 }
 
-func TestStart(t *testing.T) {
+func TestIter(t *testing.T) {
 	c := New(t)
 
-	c.Run("Start", func(c *C) {
-		err := coroutine.Start(func(co *coroutine.Coroutine, args ...any) error {
-			go safe.Do(func() error {
-				output, err := co.Yield("Hello")
-				if err != nil {
-					return err
+	c.Run("Iter", func(c *C) {
+		co := coroutine.Wrap(func(co *coroutine.Coroutine[struct{}, int], _ struct{}) (int, error) {
+			for i := 0; i < 3; i++ {
+				if _, err := co.Yield(i); err != nil {
+					return 0, err
 				}
-				c.Assert(output, DeepEquals, []any{"World"})
+			}
+
+			return 0, nil
+		})
 
-				return nil
-			})
+		go func() {
+			_ = co.Call(struct{}{})
+		}()
 
-			output, err := co.Resume("World")
+		var got []int
+		for v := range coroutine.Iter(co) {
+			got = append(got, v)
+		}
 
-			c.Assert(err, IsNil)
-			c.Assert(output, DeepEquals, "Hello")
-			return nil
+		c.Assert(got, DeepEquals, []int{0, 1, 2})
+	})
+}
+
+// TestIterExhaustionIsPrompt guards against the Resume that follows fn's
+// normal exhaustion blocking until expire's timeout: fn returns plainly
+// here, with no Terminate, so the only thing that can unblock the Resume
+// Iter issues afterwards is the coroutine's dead channel.
+func TestIterExhaustionIsPrompt(t *testing.T) {
+	c := New(t)
+
+	c.Run("IterExhaustionIsPrompt", func(c *C) {
+		co := coroutine.Wrap(func(co *coroutine.Coroutine[struct{}, int], _ struct{}) (int, error) {
+			_, err := co.Yield(0)
+
+			return 0, err
 		})
 
-		c.Assert(err, IsNil)
+		go func() {
+			_ = co.Call(struct{}{})
+		}()
+
+		start := time.Now()
+
+		var got []int
+		for v := range coroutine.Iter(co) {
+			got = append(got, v)
+		}
+
+		c.Assert(got, DeepEquals, []int{0})
+		c.Assert(time.Since(start) < 5*time.Second, Equals, true)
 	})
 }
@@ -0,0 +1,32 @@
+package coroutine
+
+import "errors"
+
+// Sentinel errors returned by the public API. Use errors.Is to
+// distinguish legitimate termination (ErrCoroutineDead) from
+// programmer error or timeouts.
+var (
+	// ErrCoroutineMissing is returned when an ID does not refer to
+	// any known coroutine, either because it was never created or
+	// because it already ran to completion and was cleaned up.
+	ErrCoroutineMissing = errors.New("coroutine: coroutine missing")
+
+	// ErrCoroutineDead is returned when an operation targets a
+	// coroutine that has already terminated.
+	ErrCoroutineDead = errors.New("coroutine: coroutine is dead")
+
+	// ErrSuspendTimeout is returned when a resume/yield handshake
+	// does not complete before the configured expire duration
+	// elapses. A Yield that times out marks the coroutine Dead; a
+	// Resume that times out leaves its status unchanged.
+	ErrSuspendTimeout = errors.New("coroutine: suspend timeout")
+
+	// ErrAlreadyStarted is returned by Call when the coroutine has
+	// already been started.
+	ErrAlreadyStarted = errors.New("coroutine: already started")
+
+	// ErrCanceled is returned when a coroutine's scoping context, or
+	// the context passed to a *Context call, is done before the
+	// resume/yield handshake completes. The coroutine is marked Dead.
+	ErrCanceled = errors.New("coroutine: canceled")
+)
@@ -0,0 +1,94 @@
+package generator
+
+// Map produces fn(v) for each value v of src.
+func Map[A, B any](src *Generator[A], fn func(A) B) *Generator[B] {
+	g := New(func(yield func(B) error) {
+		for {
+			v, ok := src.Next()
+			if !ok {
+				return
+			}
+
+			if err := yield(fn(v)); err != nil {
+				return
+			}
+		}
+	})
+	g.closeFn = src.Close
+
+	return g
+}
+
+// Filter produces the values of src for which keep returns true.
+func Filter[T any](src *Generator[T], keep func(T) bool) *Generator[T] {
+	g := New(func(yield func(T) error) {
+		for {
+			v, ok := src.Next()
+			if !ok {
+				return
+			}
+
+			if !keep(v) {
+				continue
+			}
+
+			if err := yield(v); err != nil {
+				return
+			}
+		}
+	})
+	g.closeFn = src.Close
+
+	return g
+}
+
+// Take produces at most n values of src, closing src as soon as n is
+// reached rather than leaving it suspended on an unwanted value.
+func Take[T any](src *Generator[T], n int) *Generator[T] {
+	g := New(func(yield func(T) error) {
+		for i := 0; i < n; i++ {
+			v, ok := src.Next()
+			if !ok {
+				return
+			}
+
+			if i == n-1 {
+				_ = src.Close()
+			}
+
+			if err := yield(v); err != nil {
+				return
+			}
+		}
+	})
+	g.closeFn = src.Close
+
+	return g
+}
+
+// Chain produces every value of each source in turn.
+func Chain[T any](srcs ...*Generator[T]) *Generator[T] {
+	g := New(func(yield func(T) error) {
+		for _, src := range srcs {
+			for {
+				v, ok := src.Next()
+				if !ok {
+					break
+				}
+
+				if err := yield(v); err != nil {
+					return
+				}
+			}
+		}
+	})
+	g.closeFn = func() error {
+		for _, src := range srcs {
+			_ = src.Close()
+		}
+
+		return nil
+	}
+
+	return g
+}
@@ -0,0 +1,93 @@
+// Package generator adapts coroutine into a pull-based iterator: a
+// Generator produces a sequence of values on demand, one coroutine
+// suspend point per value, and composes via Map/Filter/Take/Chain the
+// way a channel pipeline would, but without a pipeline's goroutine-per-
+// stage leak risk when a consumer stops early.
+package generator
+
+import (
+	"iter"
+
+	"github.com/cloudlibraries/coroutine"
+)
+
+// Generator produces a sequence of T values on demand. It is not safe
+// for concurrent use: pull values with Next, or range over Seq.
+type Generator[T any] struct {
+	co      *coroutine.Coroutine[struct{}, T]
+	closeFn func() error
+}
+
+// New wraps fn as a Generator: fn calls yield once per value it
+// produces and returns once it has no more to produce. fn is given only
+// the yield closure, not the underlying coroutine.
+func New[T any](fn func(yield func(T) error)) *Generator[T] {
+	co := coroutine.Wrap(func(co *coroutine.Coroutine[struct{}, T], _ struct{}) (T, error) {
+		var zero T
+
+		fn(func(v T) error {
+			_, err := co.Yield(v)
+
+			return err
+		})
+
+		// A plain return: a pending Resume wakes on the coroutine's dead
+		// channel and fails, so Next reports exhaustion without a value.
+		return zero, nil
+	})
+
+	go func() { _ = co.Call(struct{}{}) }()
+
+	return &Generator[T]{co: co}
+}
+
+// Next pulls the next value from the generator. The second return
+// value is false once the generator is exhausted, whether that is
+// because fn returned, it was Closed, or it was canceled.
+func (g *Generator[T]) Next() (T, bool) {
+	v, err := g.co.Resume(struct{}{})
+	if err != nil {
+		var zero T
+
+		return zero, false
+	}
+
+	return v, true
+}
+
+// Close stops the generator, propagating the stop upstream: a
+// combinator's Close also closes the Generator(s) it was built from,
+// so breaking out of a Seq range partway through never leaves an
+// upstream generator suspended forever waiting for a Resume that is
+// never coming.
+func (g *Generator[T]) Close() error {
+	err := g.co.Kill()
+
+	if g.closeFn != nil {
+		if cerr := g.closeFn(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// Seq adapts the generator to Go's range-over-func iterator shape.
+// Whether the range runs to exhaustion or breaks early, Close runs
+// exactly once on the way out.
+func (g *Generator[T]) Seq() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		defer g.Close()
+
+		for {
+			v, ok := g.Next()
+			if !ok {
+				return
+			}
+
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/cloudlibraries/coroutine/generator"
+)
+
+func BenchmarkGeneratorPipeline(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		g := generator.Take(
+			generator.Filter(
+				generator.Map(ints(1000), func(n int) int { return n * 2 }),
+				func(n int) bool { return n%3 == 0 },
+			),
+			50,
+		)
+
+		sum := 0
+		for v := range g.Seq() {
+			sum += v
+		}
+
+		_ = sum
+	}
+}
+
+// The channel-based stages below are the naive equivalent of the
+// Map/Filter/Take combinators: one goroutine per stage, wired up with
+// unbuffered channels. BenchmarkChannelPipeline exists to contrast its
+// cost and its leak profile with BenchmarkGeneratorPipeline: because
+// channelTake stops reading after n values, every stage upstream of it
+// is left blocked forever on a send nobody will ever receive, once the
+// benchmark iterates enough times.
+
+func channelInts(n int) <-chan int {
+	out := make(chan int)
+
+	go func() {
+		defer close(out)
+
+		for i := 0; i < n; i++ {
+			out <- i
+		}
+	}()
+
+	return out
+}
+
+func channelMap(in <-chan int, fn func(int) int) <-chan int {
+	out := make(chan int)
+
+	go func() {
+		defer close(out)
+
+		for v := range in {
+			out <- fn(v)
+		}
+	}()
+
+	return out
+}
+
+func channelFilter(in <-chan int, keep func(int) bool) <-chan int {
+	out := make(chan int)
+
+	go func() {
+		defer close(out)
+
+		for v := range in {
+			if keep(v) {
+				out <- v
+			}
+		}
+	}()
+
+	return out
+}
+
+func channelTake(in <-chan int, n int) <-chan int {
+	out := make(chan int)
+
+	go func() {
+		defer close(out)
+
+		for i := 0; i < n; i++ {
+			v, ok := <-in
+			if !ok {
+				return
+			}
+
+			out <- v
+		}
+	}()
+
+	return out
+}
+
+func BenchmarkChannelPipeline(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		out := channelTake(
+			channelFilter(
+				channelMap(channelInts(1000), func(n int) int { return n * 2 }),
+				func(n int) bool { return n%3 == 0 },
+			),
+			50,
+		)
+
+		sum := 0
+		for v := range out {
+			sum += v
+		}
+
+		_ = sum
+	}
+}
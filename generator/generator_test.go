@@ -0,0 +1,85 @@
+package generator_test
+
+import (
+	"testing"
+
+	. "github.com/frankban/quicktest"
+
+	"github.com/cloudlibraries/coroutine/generator"
+)
+
+func ints(n int) *generator.Generator[int] {
+	return generator.New(func(yield func(int) error) {
+		for i := 0; i < n; i++ {
+			if err := yield(i); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func TestSeq(t *testing.T) {
+	c := New(t)
+
+	c.Run("Seq", func(c *C) {
+		var got []int
+		for v := range ints(3).Seq() {
+			got = append(got, v)
+		}
+
+		c.Assert(got, DeepEquals, []int{0, 1, 2})
+	})
+}
+
+func TestMapFilterTake(t *testing.T) {
+	c := New(t)
+
+	c.Run("MapFilterTake", func(c *C) {
+		g := generator.Take(
+			generator.Filter(
+				generator.Map(ints(10), func(n int) int { return n * n }),
+				func(n int) bool { return n%2 == 0 },
+			),
+			3,
+		)
+
+		var got []int
+		for v := range g.Seq() {
+			got = append(got, v)
+		}
+
+		c.Assert(got, DeepEquals, []int{0, 4, 16})
+	})
+}
+
+func TestChain(t *testing.T) {
+	c := New(t)
+
+	c.Run("Chain", func(c *C) {
+		var got []int
+		for v := range generator.Chain(ints(2), ints(2)).Seq() {
+			got = append(got, v)
+		}
+
+		c.Assert(got, DeepEquals, []int{0, 1, 0, 1})
+	})
+}
+
+// TestCloseUpstream proves breaking out of a combinator's Seq partway
+// through closes the Generator it was built from too, rather than
+// leaving it suspended forever waiting for a Resume nobody will send.
+func TestCloseUpstream(t *testing.T) {
+	c := New(t)
+
+	c.Run("CloseUpstream", func(c *C) {
+		src := ints(100)
+		mapped := generator.Map(src, func(n int) int { return n })
+
+		for range mapped.Seq() {
+			break
+		}
+
+		_, ok := src.Next()
+		c.Assert(ok, Equals, false)
+	})
+}
@@ -0,0 +1,23 @@
+package coroutine
+
+import "iter"
+
+// Iter adapts a generator coroutine — one whose fn only ever yields T
+// values in response to empty resumes — into a Go 1.23 range-over-func
+// iterator. Iteration stops, without driving the coroutine any
+// further, as soon as the consumer breaks out of the range or the
+// coroutine returns an error from Resume.
+func Iter[T any](co *Coroutine[struct{}, T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			out, err := co.Resume(struct{}{})
+			if err != nil {
+				return
+			}
+
+			if !yield(out) {
+				return
+			}
+		}
+	}
+}
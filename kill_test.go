@@ -0,0 +1,94 @@
+package coroutine_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/frankban/quicktest"
+
+	"github.com/cloudlibraries/coroutine"
+)
+
+func TestKillUnblocksYield(t *testing.T) {
+	c := New(t)
+
+	c.Run("KillUnblocksYield", func(c *C) {
+		errCh := make(chan error, 1)
+
+		id := coroutine.Create(func(id coroutine.ID, inData ...interface{}) error {
+			_, err := coroutine.Yield(id, "Hello")
+			errCh <- err
+
+			return err
+		})
+
+		_, err := coroutine.Resume(id)
+		c.Assert(err, IsNil)
+
+		// Give fn time to reach and block in Yield before the Kill.
+		time.Sleep(10 * time.Millisecond)
+
+		c.Assert(coroutine.Kill(id), IsNil)
+
+		err = <-errCh
+		c.Assert(errors.Is(err, coroutine.ErrCoroutineDead), Equals, true)
+	})
+}
+
+func TestKillMissing(t *testing.T) {
+	c := New(t)
+
+	c.Run("KillMissing", func(c *C) {
+		err := coroutine.Kill("does-not-exist")
+		c.Assert(errors.Is(err, coroutine.ErrCoroutineMissing), Equals, true)
+	})
+}
+
+func TestWaitCompleted(t *testing.T) {
+	c := New(t)
+
+	c.Run("WaitCompleted", func(c *C) {
+		id := coroutine.Create(func(id coroutine.ID, inData ...interface{}) error {
+			return nil
+		})
+
+		_, err := coroutine.Resume(id)
+		c.Assert(err, IsNil)
+
+		c.Assert(coroutine.Wait(id), IsNil)
+	})
+}
+
+func TestWaitMissing(t *testing.T) {
+	c := New(t)
+
+	c.Run("WaitMissing", func(c *C) {
+		c.Assert(coroutine.Wait("does-not-exist"), IsNil)
+	})
+}
+
+func TestGenericKillAndWait(t *testing.T) {
+	c := New(t)
+
+	c.Run("GenericKillAndWait", func(c *C) {
+		co := coroutine.Wrap(func(co *coroutine.Coroutine[struct{}, int], _ struct{}) (int, error) {
+			_, err := co.Yield(0)
+
+			return 0, err
+		})
+
+		go func() {
+			_ = co.Call(struct{}{})
+		}()
+
+		_, err := co.Resume(struct{}{})
+		c.Assert(err, IsNil)
+
+		c.Assert(co.Kill(), IsNil)
+		c.Assert(co.Wait(), IsNil)
+
+		_, err = co.Resume(struct{}{})
+		c.Assert(errors.Is(err, coroutine.ErrCoroutineDead), Equals, true)
+	})
+}
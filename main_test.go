@@ -0,0 +1,14 @@
+package coroutine_test
+
+import (
+	"testing"
+
+	"go.uber.org/goleak"
+)
+
+// TestMain verifies, once every test in this package has run, that no
+// API entry point (Resume, Yield, Kill, Pool, ...) has left a goroutine
+// behind.
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
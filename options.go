@@ -1,5 +1,7 @@
 package coroutine
 
+import "context"
+
 var (
 	expire uint = 30
 )
@@ -9,3 +11,17 @@ var (
 func SetExpire(n uint) {
 	expire = n
 }
+
+// Option configures a coroutine at Wrap/Create time, regardless of its
+// Coroutine[In, Out] instantiation.
+type Option func(*core)
+
+// WithContext scopes a coroutine's lifetime to ctx: once ctx is done,
+// the coroutine transitions to Dead and any Resume/Yield blocked on it
+// is released with ErrCanceled. Pass it to Wrap or Create so a caller
+// canceling a request tree deterministically tears down its coroutines.
+func WithContext(ctx context.Context) Option {
+	return func(c *core) {
+		c.writeCtx(ctx)
+	}
+}
@@ -0,0 +1,151 @@
+package coroutine
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// Pool supervises a set of coroutines, deriving a child context per
+// coroutine so Stop cancels all of them deterministically. It replaces
+// the prior pattern of a caller doing `go Call(id)` and hoping nothing
+// leaks.
+type Pool struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	sem          chan struct{}
+	backoff      backoff.BackOff
+	panicHandler func(id ID, v interface{})
+
+	wg     sync.WaitGroup
+	size   int64
+	active int64
+
+	mu  sync.Mutex
+	ids map[ID]struct{}
+}
+
+// NewPool creates a Pool whose coroutines are all scoped to ctx: Stop,
+// or ctx being canceled by the caller, tears every one of them down.
+func NewPool(ctx context.Context, opts ...PoolOption) *Pool {
+	ctx, cancel := context.WithCancel(ctx)
+
+	p := &Pool{
+		ctx:    ctx,
+		cancel: cancel,
+		ids:    make(map[ID]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Go wraps fn as a coroutine scoped to the pool's context and runs it
+// under the pool's supervision. It returns immediately with the
+// coroutine's ID; if WithMaxConcurrent was set, the coroutine itself
+// waits for a free slot before fn actually runs.
+func (p *Pool) Go(fn func(id ID) error) ID {
+	co := p.wrap(fn)
+
+	p.wg.Add(1)
+	atomic.AddInt64(&p.size, 1)
+	atomic.AddInt64(&p.active, 1)
+
+	go p.run(co, fn)
+
+	return co.id
+}
+
+func (p *Pool) wrap(fn func(id ID) error) *AnyCoroutine {
+	co := Wrap[[]interface{}, []interface{}](func(co *AnyCoroutine, args []interface{}) ([]interface{}, error) {
+		return nil, fn(co.id)
+	}, WithContext(p.ctx))
+
+	p.mu.Lock()
+	p.ids[co.id] = struct{}{}
+	p.mu.Unlock()
+
+	return co
+}
+
+func (p *Pool) run(co *AnyCoroutine, fn func(id ID) error) {
+	defer p.wg.Done()
+	defer atomic.AddInt64(&p.active, -1)
+
+	if p.sem != nil {
+		select {
+		case p.sem <- struct{}{}:
+		case <-p.ctx.Done():
+			return
+		}
+
+		defer func() { <-p.sem }()
+	}
+
+	for {
+		panicVal, _ := co.callCatchingPanic(nil)
+
+		p.mu.Lock()
+		delete(p.ids, co.id)
+		p.mu.Unlock()
+
+		if panicVal == nil {
+			return
+		}
+
+		if p.panicHandler != nil {
+			p.panicHandler(co.id, panicVal)
+		}
+
+		if p.backoff == nil {
+			return
+		}
+
+		d := p.backoff.NextBackOff()
+		if d == backoff.Stop {
+			return
+		}
+
+		select {
+		case <-time.After(d):
+		case <-p.ctx.Done():
+			return
+		}
+
+		co = p.wrap(fn)
+	}
+}
+
+// Stop cancels every coroutine the pool owns and waits for them all to
+// terminate.
+func (p *Pool) Stop() {
+	p.cancel()
+	p.wg.Wait()
+
+	p.mu.Lock()
+	for id := range p.ids {
+		coroutines.Delete(id)
+	}
+
+	p.ids = make(map[ID]struct{})
+	p.mu.Unlock()
+}
+
+// Size reports how many coroutines the pool has ever launched via Go.
+func (p *Pool) Size() int {
+	return int(atomic.LoadInt64(&p.size))
+}
+
+// Active reports how many coroutines launched via Go have not yet
+// terminated, including ones still waiting for a free concurrency
+// slot and auto-restarted attempts.
+func (p *Pool) Active() int {
+	return int(atomic.LoadInt64(&p.active))
+}
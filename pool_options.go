@@ -0,0 +1,35 @@
+package coroutine
+
+import "github.com/cenkalti/backoff/v4"
+
+// PoolOption configures a Pool at NewPool time.
+type PoolOption func(*Pool)
+
+// WithMaxConcurrent bounds how many coroutines the pool runs at once;
+// once the bound is reached, Go still returns at once, and the new
+// coroutine waits until a slot frees up before fn runs. The default is
+// unbounded.
+func WithMaxConcurrent(n int) PoolOption {
+	return func(p *Pool) {
+		p.sem = make(chan struct{}, n)
+	}
+}
+
+// WithRestartBackoff auto-restarts a coroutine that panics, waiting
+// b.NextBackOff() between attempts; once it returns backoff.Stop the
+// pool gives up and lets the coroutine stay dead. The default is no
+// restart.
+func WithRestartBackoff(b backoff.BackOff) PoolOption {
+	return func(p *Pool) {
+		p.backoff = b
+	}
+}
+
+// WithPanicHandler is called with the ID and recovered value whenever a
+// pooled coroutine panics, before any restart configured via
+// WithRestartBackoff is attempted.
+func WithPanicHandler(fn func(id ID, v interface{})) PoolOption {
+	return func(p *Pool) {
+		p.panicHandler = fn
+	}
+}
@@ -0,0 +1,82 @@
+package coroutine_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	. "github.com/frankban/quicktest"
+
+	"github.com/cloudlibraries/coroutine"
+)
+
+func TestPoolMaxConcurrent(t *testing.T) {
+	c := New(t)
+
+	c.Run("PoolMaxConcurrent", func(c *C) {
+		pool := coroutine.NewPool(context.Background(), coroutine.WithMaxConcurrent(2))
+
+		started := make(chan struct{}, 3)
+		release := make(chan struct{})
+
+		for i := 0; i < 3; i++ {
+			pool.Go(func(id coroutine.ID) error {
+				started <- struct{}{}
+				<-release
+
+				return nil
+			})
+		}
+
+		<-started
+		<-started
+
+		select {
+		case <-started:
+			c.Fatal("third coroutine started before a slot freed")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		<-started
+
+		pool.Stop()
+		c.Assert(pool.Size(), Equals, 3)
+		c.Assert(pool.Active(), Equals, 0)
+	})
+}
+
+func TestPoolRestartBackoff(t *testing.T) {
+	c := New(t)
+
+	c.Run("PoolRestartBackoff", func(c *C) {
+		var panics []interface{}
+		done := make(chan struct{})
+
+		pool := coroutine.NewPool(
+			context.Background(),
+			coroutine.WithRestartBackoff(backoff.NewConstantBackOff(time.Millisecond)),
+			coroutine.WithPanicHandler(func(id coroutine.ID, v interface{}) {
+				panics = append(panics, v)
+			}),
+		)
+
+		attempt := 0
+		pool.Go(func(id coroutine.ID) error {
+			attempt++
+			if attempt < 3 {
+				panic("boom")
+			}
+
+			close(done)
+
+			return nil
+		})
+
+		<-done
+		pool.Stop()
+		c.Assert(attempt, Equals, 3)
+		c.Assert(panics, DeepEquals, []interface{}{"boom", "boom"})
+	})
+}
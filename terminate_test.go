@@ -0,0 +1,115 @@
+package coroutine_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/frankban/quicktest"
+
+	"github.com/cloudlibraries/coroutine"
+)
+
+// TestDeferredTerminateNoDeadlock exercises fn deferring its final value
+// via Terminate rather than Yield, with a Resume already pending when
+// the defer runs. See TestDeferredYieldWithoutPendingResumeBlocksUntilExpire
+// for the case a deferred Yield genuinely can't recover from on its own:
+// no Resume pending, and none ever coming.
+func TestDeferredTerminateNoDeadlock(t *testing.T) {
+	c := New(t)
+
+	c.Run("DeferredTerminateNoDeadlock", func(c *C) {
+		proceed := make(chan struct{})
+
+		co := coroutine.Wrap(func(co *coroutine.Coroutine[struct{}, string], _ struct{}) (string, error) {
+			defer co.Terminate("cleanup")
+
+			<-proceed
+
+			return "", nil
+		})
+
+		go func() { _ = co.Call(struct{}{}) }()
+
+		type resumeResult struct {
+			out string
+			err error
+		}
+
+		resultCh := make(chan resumeResult, 1)
+
+		go func() {
+			out, err := co.Resume(struct{}{})
+			resultCh <- resumeResult{out, err}
+		}()
+
+		// Give the Resume time to block before fn's defer runs.
+		time.Sleep(10 * time.Millisecond)
+
+		close(proceed)
+
+		result := <-resultCh
+		c.Assert(result.err, IsNil)
+		c.Assert(result.out, Equals, "cleanup")
+
+		c.Assert(co.Wait(), IsNil)
+
+		_, err := co.Resume(struct{}{})
+		c.Assert(errors.Is(err, coroutine.ErrCoroutineDead), Equals, true)
+	})
+}
+
+// TestDeferredYieldWithoutPendingResumeBlocksUntilExpire pins down
+// exactly where a plain deferred Yield remains unsafe now that
+// resume/yield also select on the coroutine's dead channel (chunk0-3):
+// with no Resume outstanding or ever issued, fn's own goroutine is the
+// one stuck waiting to send, and nothing outside that goroutine can
+// give up on its behalf. It only returns once Yield's own expire
+// timeout fires, unlike Terminate's non-blocking send, which never
+// waits on a reader at all.
+func TestDeferredYieldWithoutPendingResumeBlocksUntilExpire(t *testing.T) {
+	c := New(t)
+
+	c.Run("DeferredYieldWithoutPendingResumeBlocksUntilExpire", func(c *C) {
+		coroutine.SetExpire(1)
+		defer coroutine.SetExpire(30)
+
+		co := coroutine.Wrap(func(co *coroutine.Coroutine[struct{}, string], _ struct{}) (string, error) {
+			defer co.Yield("cleanup")
+
+			return "", nil
+		})
+
+		start := time.Now()
+
+		go func() { _ = co.Call(struct{}{}) }()
+
+		c.Assert(co.Wait(), IsNil)
+		c.Assert(time.Since(start) >= time.Second, Equals, true)
+	})
+}
+
+// TestDeferredTerminateWithoutPendingResumeIsPrompt contrasts the test
+// above: the same shape, but with Terminate in place of Yield, returns
+// immediately instead of waiting out expire.
+func TestDeferredTerminateWithoutPendingResumeIsPrompt(t *testing.T) {
+	c := New(t)
+
+	c.Run("DeferredTerminateWithoutPendingResumeIsPrompt", func(c *C) {
+		coroutine.SetExpire(1)
+		defer coroutine.SetExpire(30)
+
+		co := coroutine.Wrap(func(co *coroutine.Coroutine[struct{}, string], _ struct{}) (string, error) {
+			defer co.Terminate("cleanup")
+
+			return "", nil
+		})
+
+		start := time.Now()
+
+		go func() { _ = co.Call(struct{}{}) }()
+
+		c.Assert(co.Wait(), IsNil)
+		c.Assert(time.Since(start) < time.Second, Equals, true)
+	})
+}